@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode/mocks"
+)
+
+func prepAgreedTransfer(t *testing.T, chaincodeStub *mocks.ChaincodeStub, agreement *chaincode.AssetTransferAgreement) []byte {
+	agreementBytes, err := json.Marshal(agreement)
+	require.NoError(t, err)
+	chaincodeStub.GetPrivateDataHashReturns([]byte("datahash"), nil)
+	// call 0 builds the transfer agreement key, call 1 builds the used-nonce key
+	chaincodeStub.CreateCompositeKeyReturnsOnCall(0, transferAgreementObjectType+"id1", nil)
+	chaincodeStub.CreateCompositeKeyReturnsOnCall(1, "usedNonce"+agreement.BuyerMSP+agreement.Nonce, nil)
+	return agreementBytes
+}
+
+func TestAgreementExpired(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	assetNewOwner := &assetTransferTransientInput{
+		ID:       "id1",
+		BuyerMSP: myOrg2Msp,
+	}
+	setReturnAssetOwnerInTransientMap(t, chaincodeStub, assetNewOwner)
+	orgAsset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &orgAsset)
+
+	expiresAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	agreement := &chaincode.AssetTransferAgreement{
+		BuyerID:       myOrg2Clientid,
+		BuyerMSP:      myOrg2Msp,
+		ExpiresAt:     expiresAt,
+		Nonce:         "nonce1",
+		AgreementTxID: "agreementTx1",
+	}
+	agreementBytes := prepAgreedTransfer(t, chaincodeStub, agreement)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, agreementBytes, nil)
+	// the transaction lands after the agreement's expiry
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(expiresAt.Add(time.Second)), nil)
+
+	err := assetTransferCC.TransferAsset(transactionContext)
+	require.EqualError(t, err, "transfer agreement for asset id1 expired at 2023-01-01 00:00:00 +0000 UTC")
+}
+
+func TestAgreementReplay(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	assetNewOwner := &assetTransferTransientInput{
+		ID:       "id1",
+		BuyerMSP: myOrg2Msp,
+	}
+	setReturnAssetOwnerInTransientMap(t, chaincodeStub, assetNewOwner)
+	orgAsset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &orgAsset)
+
+	agreement := &chaincode.AssetTransferAgreement{
+		BuyerID:       myOrg2Clientid,
+		BuyerMSP:      myOrg2Msp,
+		ExpiresAt:     time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Nonce:         "nonce1",
+		AgreementTxID: "agreementTx1",
+	}
+	agreementBytes := prepAgreedTransfer(t, chaincodeStub, agreement)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, agreementBytes, nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), nil)
+	// the nonce was already recorded as consumed by a prior TransferAsset call
+	chaincodeStub.GetPrivateDataReturnsOnCall(2, []byte("agreementTx1"), nil)
+
+	err := assetTransferCC.TransferAsset(transactionContext)
+	require.EqualError(t, err, "transfer agreement nonce nonce1 for buyer MSP Org2Testmsp has already been used")
+}
+
+func TestAgreementNonceRecorded(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	assetNewOwner := &assetTransferTransientInput{
+		ID:       "id1",
+		BuyerMSP: myOrg2Msp,
+	}
+	setReturnAssetOwnerInTransientMap(t, chaincodeStub, assetNewOwner)
+	orgAsset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &orgAsset)
+
+	agreement := &chaincode.AssetTransferAgreement{
+		BuyerID:       myOrg2Clientid,
+		BuyerMSP:      myOrg2Msp,
+		ExpiresAt:     time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Nonce:         "nonce1",
+		AgreementTxID: "agreementTx1",
+	}
+	agreementBytes := prepAgreedTransfer(t, chaincodeStub, agreement)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, agreementBytes, nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), nil)
+	// the nonce has not been consumed yet
+	chaincodeStub.GetPrivateDataReturnsOnCall(2, []byte{}, nil)
+
+	err := assetTransferCC.TransferAsset(transactionContext)
+	require.NoError(t, err)
+
+	calledCollection, calledId, calledWithDataBytes := chaincodeStub.PutPrivateDataArgsForCall(3)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "usedNonce"+myOrg2Msp+"nonce1", calledId)
+	require.Equal(t, []byte("agreementTx1"), calledWithDataBytes)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
+)
+
+func TestCreateAssetSetsOwnerEndorsement(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	testAsset := &assetTransientInput{
+		ID:             "id1",
+		Type:           "testfulasset",
+		Texts:          "gray",
+		Size:           7,
+		AppraisedValue: 500,
+	}
+	setReturnAssetPropsInTransientMap(t, chaincodeStub, testAsset)
+
+	err := assetTransferCC.CreateAsset(transactionContext)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, chaincodeStub.SetPrivateDataValidationParameterCallCount())
+
+	calledCollection, calledId, calledPolicy := chaincodeStub.SetPrivateDataValidationParameterArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "id1", calledId)
+	require.NotEmpty(t, calledPolicy)
+
+	otherCollection, otherId, otherPolicy := chaincodeStub.SetPrivateDataValidationParameterArgsForCall(1)
+	require.Equal(t, myOrg1PrivCollection, otherCollection)
+	require.Equal(t, "id1", otherId)
+	require.Equal(t, calledPolicy, otherPolicy)
+}
+
+func TestTransferAssetRotatesOwnerEndorsement(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	assetNewOwner := &assetTransferTransientInput{
+		ID:       "id1",
+		BuyerMSP: myOrg2Msp,
+	}
+	setReturnAssetOwnerInTransientMap(t, chaincodeStub, assetNewOwner)
+	origAsset := chaincode.Asset{
+		ID:    "id1",
+		Type:  "testfulasset",
+		Texts: "gray",
+		Size:  7,
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
+	chaincodeStub.GetPrivateDataHashReturns([]byte("datahash"), nil)
+	agreement := &chaincode.AssetTransferAgreement{
+		BuyerID:       myOrg2Clientid,
+		BuyerMSP:      myOrg2Msp,
+		ExpiresAt:     time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Nonce:         "nonce1",
+		AgreementTxID: "agreementTx1",
+	}
+	agreementBytes := prepAgreedTransfer(t, chaincodeStub, agreement)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, agreementBytes, nil)
+	chaincodeStub.GetPrivateDataReturnsOnCall(2, []byte{}, nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), nil)
+
+	err := assetTransferCC.TransferAsset(transactionContext)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, chaincodeStub.SetPrivateDataValidationParameterCallCount())
+	calledCollection, calledId, calledPolicy := chaincodeStub.SetPrivateDataValidationParameterArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "id1", calledId)
+	require.NotEmpty(t, calledPolicy)
+
+	buyerCollection, buyerId, buyerPolicy := chaincodeStub.SetPrivateDataValidationParameterArgsForCall(1)
+	require.Equal(t, myOrg2PrivCollection, buyerCollection)
+	require.Equal(t, "id1", buyerId)
+	require.Equal(t, calledPolicy, buyerPolicy)
+}
+
+func TestTransferAssetFailsWhenCurrentEndorsementUnreadable(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+	assetNewOwner := &assetTransferTransientInput{
+		ID:       "id1",
+		BuyerMSP: myOrg2Msp,
+	}
+	setReturnAssetOwnerInTransientMap(t, chaincodeStub, assetNewOwner)
+	origAsset := chaincode.Asset{
+		ID:    "id1",
+		Type:  "testfulasset",
+		Texts: "gray",
+		Size:  7,
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
+	chaincodeStub.GetPrivateDataHashReturns([]byte("datahash"), nil)
+	chaincodeStub.GetPrivateDataValidationParameterReturns(nil, errors.New("peer unavailable"))
+
+	err := assetTransferCC.TransferAsset(transactionContext)
+	require.EqualError(t, err, "failed to read current endorsement policy for asset id1: peer unavailable")
+	require.Equal(t, 0, chaincodeStub.SetPrivateDataValidationParameterCallCount())
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// AssetHistoryEntry describes a single historical revision of an asset or transfer agreement.
+// Unlike qscc, which walks the peer's public world-state history DB, this chaincode never
+// writes its records with PutState, so GetHistoryForKey has nothing to read for them. Instead
+// CreateAsset, AgreeToTransfer and TransferAsset append an AssetHistoryEntry to a composite-key
+// log in assetCollection as they write, and GetAssetHistory/GetTransferHistory replay that log.
+// Because the log only grows from writes made under this scheme, an asset created or transferred
+// before this chaincode version was installed has no entries for that earlier activity.
+type AssetHistoryEntry struct {
+	TxID      string    `json:"txId"`
+	Timestamp time.Time `json:"timestamp"`
+	IsDelete  bool      `json:"isDelete"`
+	Value     string    `json:"value"`
+}
+
+const assetHistoryObjectType = "assetHistory"
+const transferHistoryObjectType = "transferHistory"
+
+// GetAssetHistory returns the self-maintained, oldest-first history of the public asset record
+// in assetCollection, as appended by CreateAsset and TransferAsset.
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, assetID string) ([]*AssetHistoryEntry, error) {
+	if len(assetID) == 0 {
+		return nil, fmt.Errorf("assetID field must be a non-empty string")
+	}
+
+	return readHistoryEntries(ctx, assetHistoryObjectType, assetID)
+}
+
+// GetTransferHistory returns the self-maintained, oldest-first history of the transfer agreement
+// for assetID, i.e. every buyer identity that has agreed to, or had consumed, purchasing it, as
+// appended by AgreeToTransfer and TransferAsset.
+func (s *SmartContract) GetTransferHistory(ctx contractapi.TransactionContextInterface, assetID string) ([]*AssetHistoryEntry, error) {
+	if len(assetID) == 0 {
+		return nil, fmt.Errorf("assetID field must be a non-empty string")
+	}
+
+	return readHistoryEntries(ctx, transferHistoryObjectType, assetID)
+}
+
+// recordAssetHistoryEntry appends an AssetHistoryEntry for assetID's public asset record to the
+// composite-key log that GetAssetHistory replays.
+func recordAssetHistoryEntry(ctx contractapi.TransactionContextInterface, assetID string, isDelete bool, value []byte) error {
+	return appendHistoryEntry(ctx, assetHistoryObjectType, assetID, isDelete, value)
+}
+
+// recordTransferHistoryEntry appends an AssetHistoryEntry for assetID's transfer agreement to the
+// composite-key log that GetTransferHistory replays.
+func recordTransferHistoryEntry(ctx contractapi.TransactionContextInterface, assetID string, isDelete bool, value []byte) error {
+	return appendHistoryEntry(ctx, transferHistoryObjectType, assetID, isDelete, value)
+}
+
+// appendHistoryEntry writes an AssetHistoryEntry into assetCollection under a composite key of
+// (objectType, assetID, transaction timestamp, transaction ID), so that readHistoryEntries can
+// later recover all entries for assetID, in the order they were written, via
+// GetPrivateDataByPartialCompositeKey. The timestamp is zero-padded to a fixed width so its
+// string form sorts the same as its numeric value. The timestamp alone is not a safe
+// disambiguator: it is set by the submitting client and only loosely checked against the peer's
+// clock, so a dishonest org member could craft a transaction that reuses an earlier one's
+// timestamp for the same asset and have it silently overwrite that entry. The transaction ID is
+// appended as a tie-breaker because, unlike the timestamp, it isn't a value the client can aim at
+// a specific collision.
+func appendHistoryEntry(ctx contractapi.TransactionContextInterface, objectType string, assetID string, isDelete bool, value []byte) error {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	historyKey, err := ctx.GetStub().CreateCompositeKey(objectType, []string{assetID, fmt.Sprintf("%020d", txTimestamp.AsTime().UnixNano()), txID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	entry := &AssetHistoryEntry{
+		TxID:      txID,
+		Timestamp: txTimestamp.AsTime(),
+		IsDelete:  isDelete,
+		Value:     string(value),
+	}
+	entryAsBytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset history entry into JSON: %v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(assetCollection, historyKey, entryAsBytes)
+	if err != nil {
+		return fmt.Errorf("failed to record history entry for asset %v: %v", assetID, err)
+	}
+	return nil
+}
+
+// readHistoryEntries drains every AssetHistoryEntry recorded under objectType for assetID,
+// oldest first.
+func readHistoryEntries(ctx contractapi.TransactionContextInterface, objectType string, assetID string) ([]*AssetHistoryEntry, error) {
+	resultsIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(assetCollection, objectType, []string{assetID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for asset %v: %v", assetID, err)
+	}
+	defer resultsIterator.Close()
+
+	return collectHistoryEntries(resultsIterator)
+}
+
+// collectHistoryEntries drains a StateQueryIteratorInterface of composite-key history records
+// into an ordered slice of AssetHistoryEntry.
+func collectHistoryEntries(resultsIterator shim.StateQueryIteratorInterface) ([]*AssetHistoryEntry, error) {
+	entries := []*AssetHistoryEntry{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %v", err)
+		}
+
+		var entry AssetHistoryEntry
+		err = json.Unmarshal(queryResult.Value, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history entry: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode/mocks"
+)
+
+func TestGetAssetHistoryBadInput(t *testing.T) {
+	transactionContext, _ := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	_, err := assetTransferCC.GetAssetHistory(transactionContext, "")
+	require.EqualError(t, err, "assetID field must be a non-empty string")
+}
+
+func TestGetAssetHistorySuccessful(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	ts1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts2 := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	entries := []chaincode.AssetHistoryEntry{
+		{TxID: "tx1", Timestamp: ts1, IsDelete: false, Value: `{"assetID":"id1","owner":"myOrg1Userid"}`},
+		{TxID: "tx2", Timestamp: ts2, IsDelete: false, Value: `{"assetID":"id1","owner":"myOrg2Userid"}`},
+	}
+	historyIterator := assetHistoryIterator(t, entries)
+	chaincodeStub.GetPrivateDataByPartialCompositeKeyReturns(historyIterator, nil)
+
+	// CreateAsset and TransferAsset are the only writers of this log; neither ever records an
+	// IsDelete entry, since the public asset record is replaced with a new owner, not deleted.
+	result, err := assetTransferCC.GetAssetHistory(transactionContext, "id1")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	require.Equal(t, "tx1", result[0].TxID)
+	require.False(t, result[0].IsDelete)
+	require.Equal(t, `{"assetID":"id1","owner":"myOrg1Userid"}`, result[0].Value)
+	require.True(t, result[0].Timestamp.Equal(ts1))
+
+	require.Equal(t, "tx2", result[1].TxID)
+	require.False(t, result[1].IsDelete)
+	require.Equal(t, `{"assetID":"id1","owner":"myOrg2Userid"}`, result[1].Value)
+	require.True(t, result[1].Timestamp.Equal(ts2))
+
+	calledCollection, calledObjectType, calledAttributes := chaincodeStub.GetPrivateDataByPartialCompositeKeyArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "assetHistory", calledObjectType)
+	require.Equal(t, []string{"id1"}, calledAttributes)
+}
+
+func TestGetTransferHistorySuccessful(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	entries := []chaincode.AssetHistoryEntry{
+		{TxID: "tx3", Timestamp: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), IsDelete: false, Value: "myOrg2Userid"},
+		{TxID: "tx4", Timestamp: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), IsDelete: true, Value: ""},
+	}
+	historyIterator := assetHistoryIterator(t, entries)
+	chaincodeStub.GetPrivateDataByPartialCompositeKeyReturns(historyIterator, nil)
+
+	// TransferAsset records an IsDelete entry for this log when it consumes (deletes) the
+	// agreement, so unlike the asset log above, a real write path does produce one.
+	result, err := assetTransferCC.GetTransferHistory(transactionContext, "id1")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, "tx3", result[0].TxID)
+	require.False(t, result[0].IsDelete)
+	require.Equal(t, "myOrg2Userid", result[0].Value)
+
+	require.Equal(t, "tx4", result[1].TxID)
+	require.True(t, result[1].IsDelete)
+	require.Equal(t, "", result[1].Value)
+
+	calledCollection, calledObjectType, calledAttributes := chaincodeStub.GetPrivateDataByPartialCompositeKeyArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "transferHistory", calledObjectType)
+	require.Equal(t, []string{"id1"}, calledAttributes)
+}
+
+// assetHistoryIterator builds a StateQueryIterator mock that yields the given AssetHistoryEntry
+// values in order, as GetPrivateDataByPartialCompositeKey would for a composite-key history log.
+func assetHistoryIterator(t *testing.T, entries []chaincode.AssetHistoryEntry) *mocks.StateQueryIterator {
+	historyIterator := &mocks.StateQueryIterator{}
+	for i, entry := range entries {
+		entryBytes, err := json.Marshal(entry)
+		require.NoError(t, err)
+		historyIterator.HasNextReturnsOnCall(i, true)
+		historyIterator.NextReturnsOnCall(i, &queryresult.KV{Key: entry.TxID, Value: entryBytes}, nil)
+	}
+	historyIterator.HasNextReturnsOnCall(len(entries), false)
+	return historyIterator
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
+)
+
+func TestPurgeAssetBadInput(t *testing.T) {
+	transactionContext, _ := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	err := assetTransferCC.PurgeAsset(transactionContext, "id1", -1)
+	require.EqualError(t, err, "minSecondsToLive must be a non-negative integer")
+}
+
+func TestPurgeAssetUnauthorized(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	// Asset is owned by org2's client, but org1's client is submitting the purge
+	org2Asset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg2Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &org2Asset)
+
+	err := assetTransferCC.PurgeAsset(transactionContext, "id1", 0)
+	require.EqualError(t, err, "error: submitting client identity does not own asset")
+}
+
+func TestPurgeAssetBeforeMinSecondsToLive(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	origAsset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
+	chaincodeStub.CreateCompositeKeyReturns("assetCreatedAtid1", nil)
+
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(1 * time.Second)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, []byte("1672531200"), nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(now), nil)
+
+	err := assetTransferCC.PurgeAsset(transactionContext, "id1", 200)
+	require.EqualError(t, err, "cannot purge asset id1: minSecondsToLive of 200 has not elapsed yet")
+}
+
+func TestPurgeAssetSuccessful(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	origAsset := chaincode.Asset{
+		ID:    "id1",
+		Owner: myOrg1Clientid,
+	}
+	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
+	chaincodeStub.CreateCompositeKeyReturns("assetCreatedAtid1", nil)
+
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(10 * time.Minute)
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, []byte("1672531200"), nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(now), nil)
+
+	err := assetTransferCC.PurgeAsset(transactionContext, "id1", 200)
+	require.NoError(t, err)
+
+	calledCollection, calledId := chaincodeStub.PurgePrivateDataArgsForCall(0)
+	require.Equal(t, myOrg1PrivCollection, calledCollection)
+	require.Equal(t, "id1", calledId)
+}
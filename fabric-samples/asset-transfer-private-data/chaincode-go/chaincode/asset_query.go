@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// PagedAssetResult is the paginated response returned by the chaincode's query endpoints.
+type PagedAssetResult struct {
+	Assets              []*Asset `json:"assets"`
+	Bookmark            string   `json:"bookmark"`
+	FetchedRecordsCount int32    `json:"fetchedRecordsCount"`
+}
+
+// QueryAssetsByOwner returns a page of assets from the shared assetCollection owned by owner,
+// using a CouchDB rich query against the "owner" field. An indexOwner.json index should be
+// deployed alongside the collection so this query can be serviced efficiently.
+func (s *SmartContract) QueryAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string, pageSize int32, bookmark string) (*PagedAssetResult, error) {
+	if len(owner) == 0 {
+		return nil, fmt.Errorf("owner field must be a non-empty string")
+	}
+
+	queryMap := map[string]interface{}{"selector": map[string]interface{}{"owner": owner}}
+	selector, err := json.Marshal(queryMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal owner query selector: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetPrivateDataQueryResult(assetCollection, string(selector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets by owner: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return collectPagedAssets(resultsIterator, pageSize, bookmark)
+}
+
+// GetAssetsByRange returns a page of assets from the shared assetCollection whose keys fall
+// within [startKey, endKey). shim.ChaincodeStubInterface has no paginated private-data range
+// query (GetPrivateDataByRangeWithPagination does not exist; only the public-state accessors
+// have WithPagination variants), so this falls back to GetPrivateDataByRange plus the same
+// skip-to-bookmark scan collectPagedAssets already does for the rich-query endpoints. That means
+// every page re-walks the range from the start and discards entries up to the bookmark, rather
+// than resuming server-side.
+func (s *SmartContract) GetAssetsByRange(ctx contractapi.TransactionContextInterface, startKey string, endKey string, pageSize int32, bookmark string) (*PagedAssetResult, error) {
+	resultsIterator, err := ctx.GetStub().GetPrivateDataByRange(assetCollection, startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return collectPagedAssets(resultsIterator, pageSize, bookmark)
+}
+
+// QueryAssets runs an arbitrary CouchDB rich query selector against the submitting client's own
+// org-specific implicit private collection, returning a page of matching assets.
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, couchdbSelector string, pageSize int32, bookmark string) (*PagedAssetResult, error) {
+	if len(couchdbSelector) == 0 {
+		return nil, fmt.Errorf("couchdbSelector field must be a non-empty string")
+	}
+
+	orgCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetPrivateDataQueryResult(orgCollection, couchdbSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return collectPagedAssets(resultsIterator, pageSize, bookmark)
+}
+
+// collectPagedAssets drains up to pageSize entries from resultsIterator into a PagedAssetResult.
+// The chaincode shim's private-data query APIs expose no server-side pagination, so bookmark here
+// is the last key returned by the previous page (entries up to and including it are skipped)
+// rather than an opaque CouchDB cursor.
+func collectPagedAssets(resultsIterator shim.StateQueryIteratorInterface, pageSize int32, bookmark string) (*PagedAssetResult, error) {
+	result := &PagedAssetResult{Assets: []*Asset{}}
+
+	skipping := len(bookmark) > 0
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		if skipping {
+			if queryResult.Key == bookmark {
+				skipping = false
+			}
+			continue
+		}
+
+		if pageSize > 0 && result.FetchedRecordsCount >= pageSize {
+			break
+		}
+
+		var asset Asset
+		err = json.Unmarshal(queryResult.Value, &asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset: %v", err)
+		}
+
+		result.Assets = append(result.Assets, &asset)
+		result.Bookmark = queryResult.Key
+		result.FetchedRecordsCount++
+	}
+
+	return result, nil
+}
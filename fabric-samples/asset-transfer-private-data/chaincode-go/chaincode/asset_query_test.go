@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode/mocks"
+)
+
+func TestQueryAssetsByOwnerBadInput(t *testing.T) {
+	transactionContext, _ := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	_, err := assetTransferCC.QueryAssetsByOwner(transactionContext, "", 10, "")
+	require.EqualError(t, err, "owner field must be a non-empty string")
+}
+
+func TestQueryAssetsByOwnerMultiPageWithBookmark(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	assets := []chaincode.Asset{
+		{ID: "id1", Owner: myOrg1Clientid},
+		{ID: "id2", Owner: myOrg1Clientid},
+		{ID: "id3", Owner: myOrg1Clientid},
+	}
+	queryIterator := assetQueryIterator(t, assets)
+	chaincodeStub.GetPrivateDataQueryResultReturns(queryIterator, nil)
+
+	// First page picks up the first two assets and returns id2 as the resume bookmark
+	page1, err := assetTransferCC.QueryAssetsByOwner(transactionContext, myOrg1Clientid, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page1.Assets, 2)
+	require.Equal(t, "id1", page1.Assets[0].ID)
+	require.Equal(t, "id2", page1.Assets[1].ID)
+	require.Equal(t, "id2", page1.Bookmark)
+	require.Equal(t, int32(2), page1.FetchedRecordsCount)
+
+	calledCollection, calledSelector := chaincodeStub.GetPrivateDataQueryResultArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Contains(t, calledSelector, myOrg1Clientid)
+
+	// Second page resumes from the returned bookmark and picks up the remaining asset
+	queryIterator = assetQueryIterator(t, assets)
+	chaincodeStub.GetPrivateDataQueryResultReturns(queryIterator, nil)
+	page2, err := assetTransferCC.QueryAssetsByOwner(transactionContext, myOrg1Clientid, 2, page1.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page2.Assets, 1)
+	require.Equal(t, "id3", page2.Assets[0].ID)
+}
+
+func TestQueryAssetsUsesImplicitOrgCollection(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg2()
+	assetTransferCC := chaincode.SmartContract{}
+
+	queryIterator := assetQueryIterator(t, nil)
+	chaincodeStub.GetPrivateDataQueryResultReturns(queryIterator, nil)
+
+	selector := `{"selector":{"appraisedValue":{"$gt":100}}}`
+	result, err := assetTransferCC.QueryAssets(transactionContext, selector, 10, "")
+	require.NoError(t, err)
+	require.Empty(t, result.Assets)
+
+	calledCollection, calledSelector := chaincodeStub.GetPrivateDataQueryResultArgsForCall(0)
+	require.Equal(t, myOrg2PrivCollection, calledCollection)
+	require.Equal(t, selector, calledSelector)
+}
+
+func TestGetAssetsByRange(t *testing.T) {
+	transactionContext, chaincodeStub := prepMocksAsOrg1()
+	assetTransferCC := chaincode.SmartContract{}
+
+	assets := []chaincode.Asset{
+		{ID: "id1", Owner: myOrg1Clientid},
+		{ID: "id2", Owner: myOrg1Clientid},
+	}
+	queryIterator := assetQueryIterator(t, assets)
+	chaincodeStub.GetPrivateDataByRangeReturns(queryIterator, nil)
+
+	result, err := assetTransferCC.GetAssetsByRange(transactionContext, "id1", "id9", 10, "")
+	require.NoError(t, err)
+	require.Len(t, result.Assets, 2)
+
+	calledCollection, calledStart, calledEnd := chaincodeStub.GetPrivateDataByRangeArgsForCall(0)
+	require.Equal(t, assetCollectionName, calledCollection)
+	require.Equal(t, "id1", calledStart)
+	require.Equal(t, "id9", calledEnd)
+}
+
+// assetQueryIterator builds a StateQueryIterator mock that yields the given assets in order.
+func assetQueryIterator(t *testing.T, assets []chaincode.Asset) *mocks.StateQueryIterator {
+	queryIterator := &mocks.StateQueryIterator{}
+	for i, asset := range assets {
+		assetBytes, err := json.Marshal(asset)
+		require.NoError(t, err)
+		queryIterator.HasNextReturnsOnCall(i, true)
+		queryIterator.NextReturnsOnCall(i, &queryresult.KV{Key: asset.ID, Value: assetBytes}, nil)
+	}
+	queryIterator.HasNextReturnsOnCall(len(assets), false)
+	return queryIterator
+}
@@ -0,0 +1,687 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// SmartContract of this fiction
+type SmartContract struct {
+	contractapi.Contract
+}
+
+// Asset describes the main asset details that are visible to all organizations
+type Asset struct {
+	Type  string `json:"objectType"` // Type is used to distinguish the various types of objects in state database
+	ID    string `json:"assetID"`
+	Texts string `json:"color"`
+	Size  int    `json:"size"`
+	Owner string `json:"owner"`
+}
+
+// AssetPrivateDetails describes details that are private to the owning organization
+type AssetPrivateDetails struct {
+	ID             string `json:"assetID"`
+	AppraisedValue int    `json:"appraisedValue"`
+}
+
+// assetTransientInput is unmarshalled from the "asset_properties" transient field passed to CreateAsset
+type assetTransientInput struct {
+	Type           string `json:"objectType"`
+	ID             string `json:"assetID"`
+	Texts          string `json:"color"`
+	Size           int    `json:"size"`
+	AppraisedValue int    `json:"appraisedValue"`
+}
+
+// assetTransferTransientInput is unmarshalled from the "asset_owner" transient field passed to TransferAsset
+type assetTransferTransientInput struct {
+	ID       string `json:"assetID"`
+	BuyerMSP string `json:"buyerMSP"`
+}
+
+// assetAgreementTransientInput is unmarshalled from the "asset_value" transient field passed to
+// AgreeToTransfer. ExpiresAt bounds how long the seller has to complete the transfer, and Nonce
+// lets TransferAsset detect whether this agreement has already been consumed.
+type assetAgreementTransientInput struct {
+	ID             string    `json:"assetID"`
+	AppraisedValue int       `json:"appraisedValue"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	Nonce          string    `json:"nonce"`
+}
+
+// AssetTransferAgreement records the terms a buyer agreed to in AgreeToTransfer. It is stored
+// under the transferAgreementObjectType composite key in assetCollection so the seller can verify
+// and consume it exactly once during TransferAsset.
+type AssetTransferAgreement struct {
+	BuyerID            string    `json:"buyerID"`
+	BuyerMSP           string    `json:"buyerMSP"`
+	AppraisedValueHash []byte    `json:"appraisedValueHash"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+	Nonce              string    `json:"nonce"`
+	AgreementTxID      string    `json:"agreementTxID"`
+}
+
+const assetCollection = "assetCollection"
+const transferAgreementObjectType = "transferAgreement"
+const createdAtObjectType = "assetCreatedAt"
+const usedNonceObjectType = "usedNonce"
+
+// CreateAsset creates a new asset by placing the main asset details in the assetCollection
+// that can be read by both organizations. The appraisal value is stored in the owner's
+// org-specific collection, visible only to that organization.
+func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface) error {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("error getting transient: %v", err)
+	}
+
+	// Asset properties are private, therefore they get passed in the transient field
+	transientAssetJSON, ok := transientMap["asset_properties"]
+	if !ok {
+		return fmt.Errorf("asset not found in the transient map input")
+	}
+
+	var assetInput assetTransientInput
+	err = json.Unmarshal(transientAssetJSON, &assetInput)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %v", err)
+	}
+
+	if len(assetInput.ID) == 0 {
+		return fmt.Errorf("assetID field must be a non-empty string")
+	}
+	if len(assetInput.Type) == 0 {
+		return fmt.Errorf("objectType field must be a non-empty string")
+	}
+
+	// Check if asset already exists
+	assetAsBytes, err := ctx.GetStub().GetPrivateData(assetCollection, assetInput.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get asset: %v", err)
+	} else if assetAsBytes != nil {
+		return fmt.Errorf("this asset already exists: %s", assetInput.ID)
+	}
+
+	// Get the ID of the submitting client identity
+	clientID, err := submittingClientIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Verify that the client is submitting the request to a peer in their own organization
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return fmt.Errorf("CreateAsset cannot be performed: %v", err)
+	}
+
+	asset := Asset{
+		Type:  assetInput.Type,
+		ID:    assetInput.ID,
+		Texts: assetInput.Texts,
+		Size:  assetInput.Size,
+		Owner: clientID,
+	}
+	assetJSONasBytes, err := json.Marshal(asset)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset into JSON: %v", err)
+	}
+
+	log.Printf("CreateAsset Put: collection %v, ID %v, owner %v", assetCollection, assetInput.ID, clientID)
+	err = ctx.GetStub().PutPrivateData(assetCollection, assetInput.ID, assetJSONasBytes)
+	if err != nil {
+		return fmt.Errorf("failed to put asset into private data collection: %v", err)
+	}
+
+	assetPrivateDetails := AssetPrivateDetails{
+		ID:             assetInput.ID,
+		AppraisedValue: assetInput.AppraisedValue,
+	}
+	assetPrivateDetailsAsBytes, err := json.Marshal(assetPrivateDetails)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset private details into JSON: %v", err)
+	}
+
+	orgCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+
+	log.Printf("CreateAsset Put: collection %v, ID %v", orgCollection, assetInput.ID)
+	err = ctx.GetStub().PutPrivateData(orgCollection, assetInput.ID, assetPrivateDetailsAsBytes)
+	if err != nil {
+		return fmt.Errorf("failed to put asset private details: %v", err)
+	}
+
+	err = recordAssetCreatedAt(ctx, assetInput.ID)
+	if err != nil {
+		return err
+	}
+
+	err = recordAssetHistoryEntry(ctx, assetInput.ID, false, assetJSONasBytes)
+	if err != nil {
+		return err
+	}
+
+	ownerMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get verified MSPID: %v", err)
+	}
+	err = setAssetOwnerEndorsement(ctx, assetInput.ID, ownerMSPID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AgreeToTransfer is called by a prospective buyer to agree to the appraised value of an asset.
+// It stores the buyer's agreed value privately in the buyer's own collection and records
+// the buyer's identity under a composite key in the shared assetCollection, so that the
+// seller can later verify the agreement during TransferAsset.
+func (s *SmartContract) AgreeToTransfer(ctx contractapi.TransactionContextInterface) error {
+	// Get the ID of the submitting client identity
+	clientID, err := submittingClientIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	transMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("error getting transient: %v", err)
+	}
+
+	priceJSONasBytes, ok := transMap["asset_value"]
+	if !ok {
+		return fmt.Errorf("asset_value key not found in the transient map")
+	}
+
+	var agreementInput assetAgreementTransientInput
+	err = json.Unmarshal(priceJSONasBytes, &agreementInput)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal price JSON: %v", err)
+	}
+
+	if agreementInput.AppraisedValue <= 0 {
+		return fmt.Errorf("appraisedValue field must be a positive integer")
+	}
+	if len(agreementInput.ID) == 0 {
+		return fmt.Errorf("assetID field must be a non-empty string")
+	}
+	assetID := agreementInput.ID
+
+	assetAsBytes, err := ctx.GetStub().GetPrivateData(assetCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get asset: %v", err)
+	} else if assetAsBytes == nil {
+		return fmt.Errorf("%v does not exist", assetID)
+	}
+
+	orgCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return fmt.Errorf("AgreeToTransfer cannot be performed: %v", err)
+	}
+
+	assetPrivateDetails := AssetPrivateDetails{
+		ID:             assetID,
+		AppraisedValue: agreementInput.AppraisedValue,
+	}
+	assetPrivateDetailsAsBytes, err := json.Marshal(assetPrivateDetails)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset private details into JSON: %v", err)
+	}
+
+	log.Printf("AgreeToTransfer Put: collection %v, ID %v", orgCollection, assetID)
+	err = ctx.GetStub().PutPrivateData(orgCollection, assetID, assetPrivateDetailsAsBytes)
+	if err != nil {
+		return fmt.Errorf("failed to put asset bid: %v", err)
+	}
+
+	buyerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get verified MSPID: %v", err)
+	}
+	appraisedValueHash := sha256.Sum256(assetPrivateDetailsAsBytes)
+
+	agreement := AssetTransferAgreement{
+		BuyerID:            clientID,
+		BuyerMSP:           buyerMSP,
+		AppraisedValueHash: appraisedValueHash[:],
+		ExpiresAt:          agreementInput.ExpiresAt,
+		Nonce:              agreementInput.Nonce,
+		AgreementTxID:      ctx.GetStub().GetTxID(),
+	}
+	agreementAsBytes, err := json.Marshal(agreement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset transfer agreement into JSON: %v", err)
+	}
+
+	// Record the agreement under a composite key, keyed off the asset ID, so TransferAsset can
+	// later verify and consume it exactly once.
+	transferAgreeKey, err := ctx.GetStub().CreateCompositeKey(transferAgreementObjectType, []string{assetID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(assetCollection, transferAgreeKey, agreementAsBytes)
+	if err != nil {
+		return fmt.Errorf("failed to put asset transfer agreement: %v", err)
+	}
+
+	err = recordTransferHistoryEntry(ctx, assetID, false, agreementAsBytes)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TransferAsset is called by the current owner to transfer the asset to the buyer identity
+// recorded by a prior AgreeToTransfer call, once both sides' appraised values match.
+func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface) error {
+	transMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("error getting transient: %v", err)
+	}
+
+	transferInputJSON, ok := transMap["asset_owner"]
+	if !ok {
+		return fmt.Errorf("asset_owner key not found in the transient map")
+	}
+
+	var transferInput assetTransferTransientInput
+	err = json.Unmarshal(transferInputJSON, &transferInput)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %v", err)
+	}
+
+	if len(transferInput.ID) == 0 {
+		return fmt.Errorf("assetID field must be a non-empty string")
+	}
+	if len(transferInput.BuyerMSP) == 0 {
+		return fmt.Errorf("buyerMSP field must be a non-empty string")
+	}
+	assetID := transferInput.ID
+
+	assetAsBytes, err := ctx.GetStub().GetPrivateData(assetCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get asset: %v", err)
+	} else if assetAsBytes == nil {
+		return fmt.Errorf("%v does not exist", assetID)
+	}
+
+	var asset Asset
+	err = json.Unmarshal(assetAsBytes, &asset)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal asset: %v", err)
+	}
+
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return fmt.Errorf("TransferAsset cannot be performed: %v", err)
+	}
+
+	err = verifyTransferConditions(ctx, &asset, transferInput.BuyerMSP, assetID)
+	if err != nil {
+		return fmt.Errorf("failed transfer verification: %v", err)
+	}
+
+	// The endorsement policy is rotated to the buyer's org further down, alongside the ownership
+	// change, so make sure it can actually be read before any of that is attempted.
+	_, err = ctx.GetStub().GetPrivateDataValidationParameter(assetCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to read current endorsement policy for asset %v: %v", assetID, err)
+	}
+
+	transferAgreeKey, err := ctx.GetStub().CreateCompositeKey(transferAgreementObjectType, []string{assetID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	agreement, err := readTransferAgreement(ctx, assetID, transferAgreeKey)
+	if err != nil {
+		return err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if txTimestamp.AsTime().After(agreement.ExpiresAt) {
+		return fmt.Errorf("transfer agreement for asset %v expired at %v", assetID, agreement.ExpiresAt)
+	}
+
+	usedNonceKey, err := ctx.GetStub().CreateCompositeKey(usedNonceObjectType, []string{agreement.BuyerMSP, agreement.Nonce})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+	usedNonceBytes, err := ctx.GetStub().GetPrivateData(assetCollection, usedNonceKey)
+	if err != nil {
+		return fmt.Errorf("failed to get used nonce record: %v", err)
+	}
+	if len(usedNonceBytes) > 0 {
+		return fmt.Errorf("transfer agreement nonce %v for buyer MSP %v has already been used", agreement.Nonce, agreement.BuyerMSP)
+	}
+
+	orgCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+
+	asset.Owner = agreement.BuyerID
+	newAssetJSONasBytes, err := json.Marshal(asset)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset into JSON: %v", err)
+	}
+
+	log.Printf("TransferAsset Put: collection %v, ID %v", assetCollection, assetID)
+	err = ctx.GetStub().PutPrivateData(assetCollection, assetID, newAssetJSONasBytes)
+	if err != nil {
+		return fmt.Errorf("failed to put asset: %v", err)
+	}
+
+	err = recordAssetHistoryEntry(ctx, assetID, false, newAssetJSONasBytes)
+	if err != nil {
+		return err
+	}
+
+	// Rotate the key-level endorsement policy to the buyer's org atomically with the ownership
+	// change, so that only the new owner's org can endorse future updates to this asset.
+	err = setAssetOwnerEndorsement(ctx, assetID, agreement.BuyerMSP)
+	if err != nil {
+		return fmt.Errorf("failed to rotate endorsement policy to buyer: %v", err)
+	}
+
+	// The seller's appraisal is no longer relevant once the asset has a new owner. It is purged
+	// rather than merely deleted so that the historical private RWSets are wiped from peers too.
+	err = ctx.GetStub().PurgePrivateData(orgCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to purge asset private details from seller's collection: %v", err)
+	}
+
+	err = ctx.GetStub().DelPrivateData(assetCollection, transferAgreeKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer agreement: %v", err)
+	}
+
+	err = recordTransferHistoryEntry(ctx, assetID, true, nil)
+	if err != nil {
+		return err
+	}
+
+	// Mark the nonce as consumed so this agreement cannot be replayed into a second TransferAsset call
+	err = ctx.GetStub().PutPrivateData(assetCollection, usedNonceKey, []byte(agreement.AgreementTxID))
+	if err != nil {
+		return fmt.Errorf("failed to record used nonce: %v", err)
+	}
+
+	return nil
+}
+
+// PurgeAsset is called by the current owner to fully remove their private appraisal details for
+// assetID from their org-specific collection, wiping the historical private RWSets from peers
+// rather than merely deleting the latest version. It is refused until minSecondsToLive has
+// elapsed since the asset was created, mirroring the collection's blockToLive window. The
+// chaincode API exposes no way to read the current block height, a block's header timestamp, or
+// a collection's blockToLive directly, so this is enforced in elapsed wall-clock time rather than
+// block count: a block-count check would need to guess the channel's batch timeout to convert
+// blocks to time, and since that guess has to come from somewhere, it either drifts from the
+// channel's real setting (a hardcoded constant) or is trivially supplied by the same owner the
+// check is meant to constrain (a caller-supplied argument). A direct time-based TTL avoids
+// needing that guess at all.
+func (s *SmartContract) PurgeAsset(ctx contractapi.TransactionContextInterface, assetID string, minSecondsToLive int) error {
+	if len(assetID) == 0 {
+		return fmt.Errorf("assetID field must be a non-empty string")
+	}
+	if minSecondsToLive < 0 {
+		return fmt.Errorf("minSecondsToLive must be a non-negative integer")
+	}
+
+	assetAsBytes, err := ctx.GetStub().GetPrivateData(assetCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get asset: %v", err)
+	} else if assetAsBytes == nil {
+		return fmt.Errorf("%v does not exist", assetID)
+	}
+
+	var asset Asset
+	err = json.Unmarshal(assetAsBytes, &asset)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal asset: %v", err)
+	}
+
+	clientID, err := submittingClientIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != clientID {
+		return fmt.Errorf("error: submitting client identity does not own asset")
+	}
+
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return fmt.Errorf("PurgeAsset cannot be performed: %v", err)
+	}
+
+	err = enforceMinSecondsToLive(ctx, assetID, minSecondsToLive)
+	if err != nil {
+		return err
+	}
+
+	orgCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+
+	log.Printf("PurgeAsset Purge: collection %v, ID %v", orgCollection, assetID)
+	err = ctx.GetStub().PurgePrivateData(orgCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to purge asset private details: %v", err)
+	}
+
+	return nil
+}
+
+// recordAssetCreatedAt stores the transaction timestamp at which assetID was created, under a
+// composite key in the shared assetCollection, so that PurgeAsset can later enforce minSecondsToLive.
+func recordAssetCreatedAt(ctx contractapi.TransactionContextInterface, assetID string) error {
+	createdAtKey, err := ctx.GetStub().CreateCompositeKey(createdAtObjectType, []string{assetID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(assetCollection, createdAtKey, []byte(strconv.FormatInt(txTimestamp.AsTime().Unix(), 10)))
+	if err != nil {
+		return fmt.Errorf("failed to record asset creation time: %v", err)
+	}
+	return nil
+}
+
+// enforceMinSecondsToLive refuses a purge until at least minSecondsToLive seconds have elapsed
+// since assetID was created.
+func enforceMinSecondsToLive(ctx contractapi.TransactionContextInterface, assetID string, minSecondsToLive int) error {
+	createdAtKey, err := ctx.GetStub().CreateCompositeKey(createdAtObjectType, []string{assetID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	createdAtBytes, err := ctx.GetStub().GetPrivateData(assetCollection, createdAtKey)
+	if err != nil {
+		return fmt.Errorf("failed to get asset creation time: %v", err)
+	}
+	if len(createdAtBytes) == 0 {
+		return fmt.Errorf("creation time for asset %v is not recorded", assetID)
+	}
+	createdAtUnix, err := strconv.ParseInt(string(createdAtBytes), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse asset creation time: %v", err)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	elapsedSeconds := txTimestamp.AsTime().Unix() - createdAtUnix
+	if elapsedSeconds < int64(minSecondsToLive) {
+		return fmt.Errorf("cannot purge asset %v: minSecondsToLive of %v has not elapsed yet", assetID, minSecondsToLive)
+	}
+	return nil
+}
+
+// setAssetOwnerEndorsement sets a state-based endorsement policy on assetID's key in the shared
+// assetCollection and on its private detail key in mspID's implicit collection, requiring a peer
+// from mspID's organization to endorse any future update to either key. It is called both when an
+// asset is first created and, during TransferAsset, to rotate endorsement from the seller's org to
+// the buyer's org.
+func setAssetOwnerEndorsement(ctx contractapi.TransactionContextInterface, assetID string, mspID string) error {
+	endorsementPolicy, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create endorsement policy: %v", err)
+	}
+	err = endorsementPolicy.AddOrgs(statebased.RoleTypePeer, mspID)
+	if err != nil {
+		return fmt.Errorf("failed to add org to endorsement policy: %v", err)
+	}
+	policy, err := endorsementPolicy.Policy()
+	if err != nil {
+		return fmt.Errorf("failed to marshal endorsement policy: %v", err)
+	}
+
+	err = ctx.GetStub().SetPrivateDataValidationParameter(assetCollection, assetID, policy)
+	if err != nil {
+		return fmt.Errorf("failed to set endorsement policy on asset: %v", err)
+	}
+
+	orgCollection := mspID + "PrivateCollection"
+	err = ctx.GetStub().SetPrivateDataValidationParameter(orgCollection, assetID, policy)
+	if err != nil {
+		return fmt.Errorf("failed to set endorsement policy on asset private details: %v", err)
+	}
+
+	return nil
+}
+
+// verifyTransferConditions checks that the submitting client identity owns the asset and that the
+// seller's and buyer's private appraisal values hash to the same value.
+func verifyTransferConditions(ctx contractapi.TransactionContextInterface, asset *Asset, buyerMSP string, assetID string) error {
+	clientID, err := submittingClientIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != clientID {
+		return fmt.Errorf("error: submitting client identity does not own asset")
+	}
+
+	ownerCollection, err := getCollectionName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to infer private collection name for the org: %v", err)
+	}
+	ownerAppraisedValueHash, err := ctx.GetStub().GetPrivateDataHash(ownerCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get hash of appraised value from owner's collection: %v", err)
+	}
+	if len(ownerAppraisedValueHash) == 0 {
+		return fmt.Errorf("hash for appraised value of asset %v does not exist in the owner's collection", assetID)
+	}
+
+	buyerCollection := buyerMSP + "PrivateCollection"
+	buyerAppraisedValueHash, err := ctx.GetStub().GetPrivateDataHash(buyerCollection, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get hash of appraised value from buyer's collection: %v", err)
+	}
+	if len(buyerAppraisedValueHash) == 0 {
+		return fmt.Errorf("hash for appraised value of asset %v does not exist in the buyer's collection", assetID)
+	}
+
+	if string(ownerAppraisedValueHash) != string(buyerAppraisedValueHash) {
+		return fmt.Errorf("hash for appraised value of asset %v does not match the agreed value by the buyer", assetID)
+	}
+
+	return nil
+}
+
+// readTransferAgreement returns the AssetTransferAgreement recorded by a prior AgreeToTransfer call.
+func readTransferAgreement(ctx contractapi.TransactionContextInterface, assetID string, transferAgreeKey string) (*AssetTransferAgreement, error) {
+	agreementAsBytes, err := ctx.GetStub().GetPrivateData(assetCollection, transferAgreeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer agreement: %v", err)
+	}
+	if len(agreementAsBytes) == 0 {
+		return nil, fmt.Errorf("BuyerID not found in TransferAgreement for %v", assetID)
+	}
+
+	var agreement AssetTransferAgreement
+	err = json.Unmarshal(agreementAsBytes, &agreement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer agreement: %v", err)
+	}
+	return &agreement, nil
+}
+
+// getCollectionName returns the implicit private data collection name of the submitting client's organization.
+func getCollectionName(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get verified MSPID: %v", err)
+	}
+	return clientMSPID + "PrivateCollection", nil
+}
+
+// verifyClientOrgMatchesPeerOrg ensures the submitting client identity belongs to the same
+// organization as the peer it submitted the transaction to.
+func verifyClientOrgMatchesPeerOrg(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get verified MSPID: %v", err)
+	}
+
+	peerMSPID, err := shim.GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed getting peer's MSPID: %v", err)
+	}
+
+	if clientMSPID != peerMSPID {
+		return fmt.Errorf("client from org %v is not authorized to read or write private data from an org %v peer", clientMSPID, peerMSPID)
+	}
+
+	return nil
+}
+
+// submittingClientIdentity returns the ID of the client that submitted the transaction, decoded
+// from the base64-encoded and escaped identity string returned by cid.ClientIdentity.GetID.
+func submittingClientIdentity(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clientID: %v", err)
+	}
+	decodeID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode clientID: %v", err)
+	}
+	return string(decodeID), nil
+}
@@ -6,14 +6,17 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode_test
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode"
 	"github.com/hyperledger/fabric-samples/asset-transfer-private-data/chaincode-go/chaincode/mocks"
@@ -69,6 +72,13 @@ type assetTransferTransientInput struct {
 	BuyerMSP string `json:"buyerMSP"`
 }
 
+type assetAgreementTransientInput struct {
+	ID             string    `json:"assetID"`
+	AppraisedValue int       `json:"appraisedValue"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	Nonce          string    `json:"nonce"`
+}
+
 func TestCreateAssetBadInput(t *testing.T) {
 	transactionContext, chaincodeStub := prepMocksAsOrg1()
 	assetTransferCC := chaincode.SmartContract{}
@@ -148,11 +158,11 @@ func TestAgreeToTransferBadInput(t *testing.T) {
 	transactionContext, chaincodeStub := prepMocksAsOrg1()
 	assetTransferCC := chaincode.SmartContract{}
 
-	assetPrivDetail := &chaincode.AssetPrivateDetails{
+	agreementInput := &assetAgreementTransientInput{
 		ID: "id1",
 		// no AppraisedValue
 	}
-	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, assetPrivDetail)
+	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, agreementInput)
 	origAsset := chaincode.Asset{
 		ID:    "id1",
 		Type:  "testfulasset",
@@ -165,19 +175,21 @@ func TestAgreeToTransferBadInput(t *testing.T) {
 	err := assetTransferCC.AgreeToTransfer(transactionContext)
 	require.EqualError(t, err, "appraisedValue field must be a positive integer")
 
-	assetPrivDetail = &chaincode.AssetPrivateDetails{
+	agreementInput = &assetAgreementTransientInput{
 		// no ID
 		AppraisedValue: 500,
 	}
-	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, assetPrivDetail)
+	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, agreementInput)
 	err = assetTransferCC.AgreeToTransfer(transactionContext)
 	require.EqualError(t, err, "assetID field must be a non-empty string")
 
-	assetPrivDetail = &chaincode.AssetPrivateDetails{
+	agreementInput = &assetAgreementTransientInput{
 		ID:             "id1",
 		AppraisedValue: 500,
+		ExpiresAt:      time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Nonce:          "nonce1",
 	}
-	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, assetPrivDetail)
+	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, agreementInput)
 	// asset does not exist
 	setReturnPrivateDataInStub(t, chaincodeStub, nil)
 	err = assetTransferCC.AgreeToTransfer(transactionContext)
@@ -187,11 +199,14 @@ func TestAgreeToTransferBadInput(t *testing.T) {
 func TestAgreeToTransferSuccessful(t *testing.T) {
 	transactionContext, chaincodeStub := prepMocksAsOrg1()
 	assetTransferCC := chaincode.SmartContract{}
-	assetPrivDetail := &chaincode.AssetPrivateDetails{
+	expiresAt := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	agreementInput := &assetAgreementTransientInput{
 		ID:             "id1",
 		AppraisedValue: 500,
+		ExpiresAt:      expiresAt,
+		Nonce:          "nonce1",
 	}
-	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, assetPrivDetail)
+	setReturnAssetPrivateDetailsInTransientMap(t, chaincodeStub, agreementInput)
 	origAsset := chaincode.Asset{
 		ID:    "id1",
 		Type:  "testfulasset",
@@ -201,19 +216,33 @@ func TestAgreeToTransferSuccessful(t *testing.T) {
 	}
 	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
 	chaincodeStub.CreateCompositeKeyReturns(transferAgreementObjectType+"id1", nil)
+	chaincodeStub.GetTxIDReturns("agreementTx1")
 	err := assetTransferCC.AgreeToTransfer(transactionContext)
 	require.NoError(t, err)
 
-	expectedDataBytes, err := json.Marshal(assetPrivDetail)
+	expectedPrivateDetails := &chaincode.AssetPrivateDetails{ID: "id1", AppraisedValue: 500}
+	expectedDataBytes, err := json.Marshal(expectedPrivateDetails)
+	require.NoError(t, err)
 	calledCollection, calledId, calledWithDataBytes := chaincodeStub.PutPrivateDataArgsForCall(0)
 	require.Equal(t, myOrg1PrivCollection, calledCollection)
 	require.Equal(t, "id1", calledId)
 	require.Equal(t, expectedDataBytes, calledWithDataBytes)
 
+	appraisedValueHash := sha256.Sum256(expectedDataBytes)
+	expectedAgreement := &chaincode.AssetTransferAgreement{
+		BuyerID:            myOrg1Clientid,
+		BuyerMSP:           myOrg1Msp,
+		AppraisedValueHash: appraisedValueHash[:],
+		ExpiresAt:          expiresAt,
+		Nonce:              "nonce1",
+		AgreementTxID:      "agreementTx1",
+	}
+	expectedAgreementBytes, err := json.Marshal(expectedAgreement)
+	require.NoError(t, err)
 	calledCollection, calledId, calledWithDataBytes = chaincodeStub.PutPrivateDataArgsForCall(1)
 	require.Equal(t, assetCollectionName, calledCollection)
 	require.Equal(t, transferAgreementObjectType+"id1", calledId)
-	require.Equal(t, []byte(myOrg1Clientid), calledWithDataBytes)
+	require.Equal(t, expectedAgreementBytes, calledWithDataBytes)
 }
 func TestTransferAssetBadInput(t *testing.T) {
 	transactionContext, chaincodeStub := prepMocksAsOrg1()
@@ -257,11 +286,23 @@ func TestTransferAssetSuccessful(t *testing.T) {
 	setReturnPrivateDataInStub(t, chaincodeStub, &origAsset)
 	// to ensure we pass data hash verification
 	chaincodeStub.GetPrivateDataHashReturns([]byte("datahash"), nil)
-	// to ensure that ReadTransferAgreement call returns org2 client ID
-	chaincodeStub.GetPrivateDataReturnsOnCall(1, []byte(myOrg2Clientid), nil)
+	agreement := &chaincode.AssetTransferAgreement{
+		BuyerID:       myOrg2Clientid,
+		BuyerMSP:      myOrg2Msp,
+		ExpiresAt:     time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Nonce:         "nonce1",
+		AgreementTxID: "agreementTx1",
+	}
+	agreementBytes, err := json.Marshal(agreement)
+	require.NoError(t, err)
+	// to ensure that readTransferAgreement call returns the recorded agreement
+	chaincodeStub.GetPrivateDataReturnsOnCall(1, agreementBytes, nil)
+	// to ensure the nonce has not already been consumed
+	chaincodeStub.GetPrivateDataReturnsOnCall(2, []byte{}, nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), nil)
 	chaincodeStub.CreateCompositeKeyReturns(transferAgreementObjectType+"id1", nil)
 
-	err := assetTransferCC.TransferAsset(transactionContext)
+	err = assetTransferCC.TransferAsset(transactionContext)
 	require.NoError(t, err)
 	// Validate PutPrivateData calls
 	expectedNewAsset := origAsset
@@ -272,11 +313,13 @@ func TestTransferAssetSuccessful(t *testing.T) {
 	require.Equal(t, assetCollectionName, calledCollection)
 	require.Equal(t, "id1", calledId)
 	require.Equal(t, expectedNewAssetBytes, calledWithAssetBytes)
-	calledCollection, calledId = chaincodeStub.DelPrivateDataArgsForCall(0)
+
+	// The seller's appraisal is purged, not merely deleted, so that its history is wiped too
+	calledCollection, calledId = chaincodeStub.PurgePrivateDataArgsForCall(0)
 	require.Equal(t, myOrg1PrivCollection, calledCollection)
 	require.Equal(t, "id1", calledId)
 
-	calledCollection, calledId = chaincodeStub.DelPrivateDataArgsForCall(1)
+	calledCollection, calledId = chaincodeStub.DelPrivateDataArgsForCall(0)
 	require.Equal(t, assetCollectionName, calledCollection)
 	require.Equal(t, transferAgreementObjectType+"id1", calledId)
 
@@ -376,11 +419,11 @@ func prepMocks(orgMSP, clientId string) (*mocks.TransactionContext, *mocks.Chain
 	return transactionContext, chaincodeStub
 }
 
-func setReturnAssetPrivateDetailsInTransientMap(t *testing.T, chaincodeStub *mocks.ChaincodeStub, assetPrivDetail *chaincode.AssetPrivateDetails) []byte {
+func setReturnAssetPrivateDetailsInTransientMap(t *testing.T, chaincodeStub *mocks.ChaincodeStub, agreementInput *assetAgreementTransientInput) []byte {
 	assetOwnerBytes := []byte{}
-	if assetPrivDetail != nil {
+	if agreementInput != nil {
 		var err error
-		assetOwnerBytes, err = json.Marshal(assetPrivDetail)
+		assetOwnerBytes, err = json.Marshal(agreementInput)
 		require.NoError(t, err)
 	}
 	assetPropMap := map[string][]byte{